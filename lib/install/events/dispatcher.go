@@ -0,0 +1,99 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// subscriberBufferSize bounds the number of events buffered for a single
+// subscriber before the dispatcher starts dropping the newest ones for it.
+const subscriberBufferSize = 64
+
+// NewDispatcher returns a new, ready to use Dispatcher.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{subscribers: make(map[int]*subscriber)}
+}
+
+// Dispatcher fans a single stream of InstallEvents out to any number of
+// subscribers. Each subscriber has its own bounded buffer so a slow
+// consumer cannot block publication of events to the rest - events that
+// don't fit are dropped and counted instead.
+type Dispatcher struct {
+	mu          sync.Mutex
+	subscribers map[int]*subscriber
+	nextID      int
+}
+
+type subscriber struct {
+	filter EventFilter
+	eventC chan InstallEvent
+}
+
+// Subscribe registers a new subscriber matching filter and returns the
+// channel it receives events on together with a function that cancels the
+// subscription and releases its resources. A nil filter receives every
+// event.
+func (d *Dispatcher) Subscribe(filter EventFilter) (<-chan InstallEvent, func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id := d.nextID
+	d.nextID++
+	sub := &subscriber{filter: filter, eventC: make(chan InstallEvent, subscriberBufferSize)}
+	d.subscribers[id] = sub
+	return sub.eventC, func() { d.unsubscribe(id) }
+}
+
+func (d *Dispatcher) unsubscribe(id int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	sub, ok := d.subscribers[id]
+	if !ok {
+		return
+	}
+	delete(d.subscribers, id)
+	close(sub.eventC)
+}
+
+// Publish fans event out to every subscriber whose filter matches it.
+func (d *Dispatcher) Publish(event InstallEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, sub := range d.subscribers {
+		if sub.filter != nil && !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.eventC <- event:
+		default:
+			atomic.AddUint64(&droppedEvents, 1)
+			log.WithField("event", event).Warn("Dropping install event for slow subscriber.")
+		}
+	}
+}
+
+// droppedEvents counts events dropped because a subscriber's buffer was full.
+var droppedEvents uint64
+
+// DroppedEvents returns the number of events dropped so far because a
+// subscriber was not keeping up. Exposed for metrics collection.
+func DroppedEvents() uint64 {
+	return atomic.LoadUint64(&droppedEvents)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events defines the typed event stream published by the installer
+// over the course of an install operation. It lets other subsystems
+// (monitoring, audit, external controllers) react to semantic install
+// events without depending on the installer's internal progress format.
+package events
+
+import "time"
+
+// InstallEvent is implemented by every event the installer publishes.
+// The set of variants is closed - consumers are expected to type switch
+// over the concrete list below rather than defining their own.
+type InstallEvent interface {
+	isInstallEvent()
+}
+
+// OperationStarted is published once the install operation has been created.
+type OperationStarted struct {
+	// Time is when the operation was created
+	Time time.Time
+}
+
+func (OperationStarted) isInstallEvent() {}
+
+// PhaseStarted is published when a plan phase begins executing.
+type PhaseStarted struct {
+	// PhaseID identifies the phase
+	PhaseID string
+	// NodeID is the node the phase is executing on, if any
+	NodeID string
+	// Time is when the phase started
+	Time time.Time
+}
+
+func (PhaseStarted) isInstallEvent() {}
+
+// PhaseCompleted is published when a plan phase finishes, successfully or not.
+type PhaseCompleted struct {
+	// PhaseID identifies the phase
+	PhaseID string
+	// Duration is how long the phase took to execute
+	Duration time.Duration
+	// Err is set if the phase failed
+	Err error
+}
+
+func (PhaseCompleted) isInstallEvent() {}
+
+// AgentJoined is published when an install agent connects to the operation.
+type AgentJoined struct {
+	// Role is the agent's cluster role
+	Role string
+	// Addr is the address the agent connected from
+	Addr string
+}
+
+func (AgentJoined) isInstallEvent() {}
+
+// EndpointsReady is published once the cluster's install endpoints can be
+// resolved.
+type EndpointsReady struct {
+	// Endpoints lists the available endpoint URLs
+	Endpoints []string
+}
+
+func (EndpointsReady) isInstallEvent() {}
+
+// InstallAborted is published when the operation is torn down before
+// completing.
+type InstallAborted struct {
+	// Reason describes why the operation was aborted
+	Reason error
+}
+
+func (InstallAborted) isInstallEvent() {}
+
+// InstallCompleted is published once the operation has finished.
+type InstallCompleted struct {
+	// Duration is the total time the operation took
+	Duration time.Duration
+	// Status is the final operation status
+	Status string
+}
+
+func (InstallCompleted) isInstallEvent() {}
+
+// Draining is published periodically while the installer is waiting for
+// the currently running phase to finish before shutting down.
+type Draining struct {
+	// Elapsed is how long the installer has been draining so far
+	Elapsed time.Duration
+}
+
+func (Draining) isInstallEvent() {}
+
+// HookFailed is published when a user-defined lifecycle hook exits with an
+// error.
+type HookFailed struct {
+	// HookType identifies the lifecycle point the hook ran at
+	HookType string
+	// PhaseID is the phase the hook was scoped to, empty for
+	// install-level hooks
+	PhaseID string
+	// Image is the container image the hook ran in
+	Image string
+	// Err is the error the hook failed with
+	Err error
+}
+
+func (HookFailed) isInstallEvent() {}
+
+// EventFilter decides whether a subscriber is interested in event.
+// A nil EventFilter matches every event.
+type EventFilter func(event InstallEvent) bool
@@ -0,0 +1,183 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	installevents "github.com/gravitational/gravity/lib/install/events"
+
+	"github.com/gravitational/trace"
+	log "github.com/sirupsen/logrus"
+)
+
+// HookType identifies the point in the install lifecycle a hook runs at.
+type HookType string
+
+const (
+	// HookPreInstall runs once before the operation plan starts executing
+	HookPreInstall HookType = "preInstall"
+	// HookPrePhase runs before a matching phase executes
+	HookPrePhase HookType = "prePhase"
+	// HookPostPhase runs after a matching phase completes, successfully or not
+	HookPostPhase HookType = "postPhase"
+	// HookPostInstall runs once after the operation plan has completed
+	HookPostInstall HookType = "postInstall"
+)
+
+// Hook describes a user-defined command to run at a specific point of the
+// install lifecycle, declared by the cluster image manifest.
+type Hook struct {
+	// Type is the lifecycle point this hook runs at
+	Type HookType
+	// PhaseID is a glob matched against the ID of the phase the hook is
+	// scoped to. Only meaningful for HookPrePhase/HookPostPhase
+	PhaseID string
+	// Image is the container image the hook's command runs in
+	Image string
+	// Command is the command to execute inside Image
+	Command []string
+	// Timeout bounds how long the hook is allowed to run before it is killed
+	Timeout time.Duration
+	// IgnoreFailure, if true, lets the surrounding phase continue even if
+	// the hook exits with a non-zero status
+	IgnoreFailure bool
+}
+
+// hooksForPhase returns the hooks of the given type that match phaseID,
+// preserving the order they were declared in.
+func hooksForPhase(hooks []Hook, hookType HookType, phaseID string) (matched []Hook) {
+	for _, hook := range hooks {
+		if hook.Type != hookType {
+			continue
+		}
+		if hook.Type == HookPreInstall || hook.Type == HookPostInstall {
+			matched = append(matched, hook)
+			continue
+		}
+		if ok, _ := path.Match(hook.PhaseID, phaseID); ok {
+			matched = append(matched, hook)
+		}
+	}
+	return matched
+}
+
+// runHooks runs every hook of hookType scoped to phaseID in order. The first
+// hook that fails without IgnoreFailure set aborts the remaining hooks and
+// is returned as an error.
+//
+// If the currently executing machine already recorded (phaseID, hookType)
+// as having run to completion - which is only possible once the operation
+// plan has an FSM behind it, i.e. on resume via executeOperation - running
+// them again is skipped, so a crash-and-resume of an install-level hook
+// documented as running "once" doesn't silently run it a second time.
+func (i *Installer) runHooks(ctx context.Context, hookType HookType, phaseID string) error {
+	if machine := i.currentMachine(); machine != nil {
+		ok, err := machine.PhaseHookStatus(phaseID, string(hookType))
+		if err != nil {
+			i.WithError(err).Warn("Failed to query hook status in the operation plan.")
+		} else if ok {
+			return nil
+		}
+	}
+	for _, hook := range hooksForPhase(i.config.Hooks, hookType, phaseID) {
+		if err := i.runHook(ctx, hook, phaseID); err != nil && !hook.IgnoreFailure {
+			return trace.Wrap(err, "hook %v/%v failed", hookType, phaseID)
+		}
+	}
+	return nil
+}
+
+// runHook executes hook inside the planet container environment with a
+// kubeconfig scoped to the phase's hook (rather than the full cluster-admin
+// config) and a bounded timeout. The output RunHook returns is appended to
+// the user log once the hook exits, so it is uploaded alongside the standard
+// user log - on both success and failure, since a failing hook's output is
+// usually exactly what the user needs to see. The output is not sent as
+// progress events; a failure is published as a HookFailed event. Whenever an
+// FSM is driving the operation, the hook's exit status, whatever its type,
+// is also recorded against phaseID in the operation plan, so a subsequent
+// resume of the plan can tell the hook already ran.
+func (i *Installer) runHook(ctx context.Context, hook Hook, phaseID string) error {
+	timeout := hook.Timeout
+	if timeout == 0 {
+		timeout = defaults.HookTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	i.WithFields(log.Fields{
+		"hook":  hook.Type,
+		"phase": phaseID,
+		"image": hook.Image,
+	}).Info("Running install hook.")
+	kubeconfig, err := i.config.Process.UsersService().GetHookKubeconfig(hook.Type, phaseID)
+	if err != nil {
+		return trace.Wrap(err, "failed to provision scoped kubeconfig for hook")
+	}
+	output, err := i.config.Process.HookService().RunHook(hookCtx, hook.Image, hook.Command, kubeconfig)
+	i.recordHookStatus(hook, phaseID, err)
+	if uploadErr := i.uploadHookLog(hook, phaseID, output); uploadErr != nil {
+		i.WithError(uploadErr).Warn("Failed to upload hook log.")
+	}
+	if err != nil {
+		i.publish(installevents.HookFailed{
+			HookType: string(hook.Type),
+			PhaseID:  phaseID,
+			Image:    hook.Image,
+			Err:      err,
+		})
+		return trace.Wrap(err)
+	}
+	return nil
+}
+
+// recordHookStatus persists hook's exit status against phaseID in the
+// operation plan driven by the currently executing machine, if any, so
+// Complete/resume can tell whether the hook already ran rather than
+// re-running it. Hooks that run before the FSM for the operation exists
+// yet (executeDetachable's pre-install hook, in particular) have nothing
+// to record against and are skipped - those are only re-driven by a full
+// process restart, not by resuming an existing plan, and a full restart
+// re-runs install-level hooks by design.
+func (i *Installer) recordHookStatus(hook Hook, phaseID string, hookErr error) {
+	machine := i.currentMachine()
+	if machine == nil {
+		return
+	}
+	if err := machine.SetPhaseHookStatus(phaseID, string(hook.Type), hookErr); err != nil {
+		i.WithError(err).Warn("Failed to persist hook status in the operation plan.")
+	}
+}
+
+// uploadHookLog appends the hook's output to the user log file so it gets
+// picked up by uploadInstallLog alongside the rest of the install log.
+func (i *Installer) uploadHookLog(hook Hook, phaseID string, output []byte) error {
+	file, err := os.OpenFile(i.config.UserLogFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "--- hook %v/%v (%v) ---\n%s\n", hook.Type, phaseID, hook.Image, output)
+	return trace.Wrap(w.Flush())
+}
@@ -23,12 +23,15 @@ import (
 	"io"
 	"net"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/gravitational/gravity/lib/constants"
 	"github.com/gravitational/gravity/lib/defaults"
 	"github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/install/engine"
+	installevents "github.com/gravitational/gravity/lib/install/events"
+	"github.com/gravitational/gravity/lib/install/logging"
 	installpb "github.com/gravitational/gravity/lib/install/proto"
 	"github.com/gravitational/gravity/lib/install/server"
 	"github.com/gravitational/gravity/lib/localenv"
@@ -46,6 +49,11 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// errDetached is returned internally by RunWithContext to signal that the
+// client context was cancelled and the operation has been detached rather
+// than aborted. It never escapes to callers.
+var errDetached = trace.Errorf("operation detached")
+
 // New returns a new instance of the unstarted installer server.
 // ctx is only used for the duration of this call and is not stored beyond that.
 // Use Serve to start server operation
@@ -70,7 +78,9 @@ func New(ctx context.Context, config RuntimeConfig) (installer *Installer, err e
 		cancel:      cancel,
 		agent:       agent,
 		executeSema: make(chan struct{}, 1),
+		events:      installevents.NewDispatcher(),
 	}
+	go installer.relayProgressEvents()
 	if err := installer.maybeStartAgent(); err != nil {
 		return nil, trace.Wrap(err)
 	}
@@ -78,12 +88,27 @@ func New(ctx context.Context, config RuntimeConfig) (installer *Installer, err e
 }
 
 // Run runs the server operation
-func (i *Installer) Run(listener net.Listener) (err error) {
+func (i *Installer) Run(listener net.Listener) error {
+	return i.RunWithContext(context.Background(), listener)
+}
+
+// RunWithContext runs the server operation the same way Run does but
+// additionally watches clientCtx for cancellation. clientCtx tracks the
+// connected client (e.g. the gravity CLI's gRPC stream) and is distinct
+// from i.ctx, which tracks the installer's own lifetime. Cancelling
+// clientCtx does not abort the operation - it only means the client has
+// gone away, so the running operation is detached and left executing in
+// the background. A reconnecting client can call Run again to reattach.
+func (i *Installer) RunWithContext(clientCtx context.Context, listener net.Listener) (err error) {
 	defer func() {
 		if installpb.IsAbortedErr(err) {
 			i.abort()
 			return
 		}
+		if err == errDetached {
+			err = nil
+			return
+		}
 		i.stop()
 	}()
 	errC := make(chan error, 1)
@@ -98,15 +123,22 @@ func (i *Installer) Run(listener net.Listener) (err error) {
 	case <-i.doneC:
 		// Main operation execution done
 		return nil
+	case <-clientCtx.Done():
+		if err := i.Detach(context.Background()); err != nil {
+			i.WithError(err).Warn("Failed to detach.")
+		}
+		return errDetached
 	}
 }
 
 // Stop stops the server and releases resources allocated by the installer.
+// Rather than tearing the operation down immediately, it drains: the
+// currently running phase (if any) is given up to GracefulShutdownTimeout
+// to finish before the server is interrupted.
 // Implements signals.Stopper
 func (i *Installer) Stop(ctx context.Context) error {
 	i.Info("Stop.")
-	i.server.Interrupt(ctx)
-	return nil
+	return trace.Wrap(i.drain(ctx))
 }
 
 // Interface defines the interface of the installer as presented
@@ -130,28 +162,40 @@ type Interface interface {
 	CompleteFinalInstallStep(key ops.SiteOperationKey, delay time.Duration) error
 	// PrintStep publishes a progress entry described with (format, args)
 	PrintStep(format string, args ...interface{})
+	// Detach marks the running operation as detached and lets it continue
+	// executing in the background, independent of the calling client
+	Detach(ctx context.Context) error
+	// Subscribe returns a channel of install lifecycle events matching
+	// filter, and a function that cancels the subscription
+	Subscribe(filter installevents.EventFilter) (<-chan installevents.InstallEvent, func())
+	// NotifyAgentJoined is invoked by the engine/agent service when an
+	// install agent connects
+	NotifyAgentJoined(role, addr string)
 }
 
 // NotifyOperationAvailable is invoked by the engine to notify the server
 // that the operation has been created.
 // Implements Interface
 func (i *Installer) NotifyOperationAvailable(op ops.SiteOperation) error {
+	ctx := logging.WithOperation(i.ctx, op.Key())
+	logger := logging.FromContext(ctx)
+	i.publish(installevents.OperationStarted{Time: op.Created})
 	if err := i.startAgent(op); err != nil {
 		return trace.Wrap(err)
 	}
 	i.addAborter(signals.StopperFunc(func(ctx context.Context) error {
-		i.WithField("operation", op.ID).Info("Aborting agent service.")
+		logger.WithField("operation", op.ID).Info("Aborting agent service.")
 		return trace.Wrap(i.config.Process.AgentService().AbortAgents(ctx, op.Key()))
 	}))
 	if err := i.upsertAdminAgent(op.SiteDomain); err != nil {
 		return trace.Wrap(err)
 	}
 	go ProgressLooper{
-		FieldLogger:  i.FieldLogger,
+		FieldLogger:  logger,
 		Operator:     i.config.Operator,
 		OperationKey: op.Key(),
 		Dispatcher:   i.server,
-	}.Run(i.ctx)
+	}.Run(ctx)
 
 	return nil
 }
@@ -165,6 +209,8 @@ func (i *Installer) NewCluster() ops.NewSiteRequest {
 // ExecuteOperation executes the specified operation to completion.
 // Implements Interface
 func (i *Installer) ExecuteOperation(operationKey ops.SiteOperationKey) error {
+	ctx := logging.WithOperation(i.ctx, operationKey)
+	logger := logging.FromContext(ctx)
 	err := initOperationPlan(i.config.Operator, i.config.Planner)
 	if err != nil && !trace.IsAlreadyExists(err) {
 		return trace.Wrap(err)
@@ -173,12 +219,14 @@ func (i *Installer) ExecuteOperation(operationKey ops.SiteOperationKey) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
-	err = machine.ExecutePlan(i.ctx, utils.DiscardProgress)
+	i.setCurrentMachine(machine)
+	defer i.setCurrentMachine(nil)
+	err = machine.ExecutePlan(ctx, utils.DiscardProgress)
 	if err != nil {
-		i.WithError(err).Warn("Failed to execute operation plan.")
+		logger.WithError(err).Warn("Failed to execute operation plan.")
 	}
 	if completeErr := machine.Complete(err); completeErr != nil {
-		i.WithError(completeErr).Warn("Failed to complete operation.")
+		logger.WithError(completeErr).Warn("Failed to complete operation.")
 		if err == nil {
 			err = completeErr
 		}
@@ -234,19 +282,164 @@ func (i *Installer) PrintStep(format string, args ...interface{}) {
 	i.server.Send(event)
 }
 
+// Subscribe returns a channel of install lifecycle events matching filter,
+// and a function that cancels the subscription. Implements Interface
+func (i *Installer) Subscribe(filter installevents.EventFilter) (<-chan installevents.InstallEvent, func()) {
+	return i.events.Subscribe(filter)
+}
+
+// publish publishes event on the installer's event bus
+func (i *Installer) publish(event installevents.InstallEvent) {
+	i.events.Publish(event)
+}
+
+// NotifyAgentJoined publishes an AgentJoined event for the agent identified
+// by role/addr. Implements Interface
+func (i *Installer) NotifyAgentJoined(role, addr string) {
+	i.publish(installevents.AgentJoined{Role: role, Addr: addr})
+}
+
+// relayProgressEvents is the built-in subscriber that stringifies published
+// install events into the legacy ProgressEntry stream consumed by the CLI,
+// so existing output keeps working unchanged for consumers that only know
+// about server.Event.
+func (i *Installer) relayProgressEvents() {
+	eventC, cancel := i.events.Subscribe(nil)
+	defer cancel()
+	for event := range eventC {
+		if message, ok := formatProgressMessage(event); ok {
+			i.server.Send(server.Event{Progress: &ops.ProgressEntry{Message: message}})
+		}
+	}
+}
+
+// formatProgressMessage renders event as a human-readable progress message
+// for the ProgressEntry stream. ok is false for events that get no message
+// here (InstallCompleted, EndpointsReady and unknown variants).
+func formatProgressMessage(event installevents.InstallEvent) (message string, ok bool) {
+	switch event := event.(type) {
+	case installevents.OperationStarted:
+		return "Starting operation", true
+	case installevents.PhaseStarted:
+		return fmt.Sprintf("Executing phase %q", event.PhaseID), true
+	case installevents.PhaseCompleted:
+		if event.Err != nil {
+			return fmt.Sprintf("Phase %q failed: %v", event.PhaseID, event.Err), true
+		}
+		return fmt.Sprintf("Phase %q completed in %v", event.PhaseID, event.Duration), true
+	case installevents.AgentJoined:
+		return fmt.Sprintf("Agent %q connected from %v", event.Role, event.Addr), true
+	case installevents.HookFailed:
+		return fmt.Sprintf("Hook %v for phase %q failed: %v", event.HookType, event.PhaseID, event.Err), true
+	case installevents.InstallAborted:
+		return fmt.Sprintf("Operation aborted: %v", event.Reason), true
+	case installevents.InstallCompleted:
+		// Sent synchronously by sendElapsedTime instead of through this
+		// relay, so the message is guaranteed to precede sendCompletionEvent's
+		// own direct Send - skip it here to avoid sending it twice.
+		return "", false
+	case installevents.Draining:
+		return fmt.Sprintf("Waiting for the current phase to finish (draining for %v)", event.Elapsed), true
+	default:
+		return "", false
+	}
+}
+
 // Execute executes the install operation using the configured engine.
 // Implements server.Executor
 func (i *Installer) Execute(ctx context.Context, phase *installpb.ExecuteRequest_Phase) error {
-	i.waitForExecuteToken(ctx)
-	defer i.releaseExecuteToken()
-	i.WithField("phase", phase).Info("Execute.")
+	logging.FromContext(ctx).WithField("phase", phase).Info("Execute.")
 	if phase != nil {
+		i.waitForExecuteToken(ctx)
+		defer i.releaseExecuteToken()
+		if !i.beginExecute() {
+			return trace.BadParameter("installer is shutting down, not accepting new phase executions")
+		}
+		defer i.executing.Done()
 		return i.executePhase(*phase)
 	}
-	err := i.config.Engine.Execute(i.ctx, i, i.config.Config)
+	return i.executeDetachable(ctx)
+}
+
+// executeDetachable runs the configured engine to completion in a supervisor
+// goroutine that outlives this call. The supervisor drives the engine
+// against i.ctx - the installer's own lifetime context - so if clientCtx
+// is cancelled before the engine finishes, Execute detaches and returns
+// without tearing down i.config.Process, the agents or the stoppers.
+//
+// If a previous call already has a supervisor goroutine running - the
+// reattach case, where a reconnecting client calls Run again after a
+// detach - this attaches to that goroutine's result instead of starting a
+// second, concurrent install: it waits on the same completion handle
+// rather than calling i.config.Engine.Execute a second time.
+//
+// The execute token and the executing wait group are acquired here but
+// released by the supervisor goroutine itself, not by this call - holding
+// them only until RunWithContext/Execute returns would let a reconnecting
+// client start a second, concurrent execution against the still-running
+// goroutine, and would let drain() observe executing.Wait() returning
+// immediately and tear the server down while the engine is still installing.
+func (i *Installer) executeDetachable(clientCtx context.Context) error {
+	if execution := i.attachToRunningExecution(); execution != nil {
+		return trace.Wrap(i.awaitExecution(clientCtx, execution))
+	}
+	i.waitForExecuteToken(clientCtx)
+	if !i.beginExecute() {
+		i.releaseExecuteToken()
+		return trace.BadParameter("installer is shutting down, not accepting new phase executions")
+	}
+	if err := i.runHooks(i.ctx, HookPreInstall, ""); err != nil {
+		i.executing.Done()
+		i.releaseExecuteToken()
+		return trace.Wrap(err)
+	}
+	execution := i.beginExecution()
+	go func() {
+		defer i.executing.Done()
+		defer i.releaseExecuteToken()
+		err := i.config.Engine.Execute(i.ctx, i, i.config.Config)
+		if hookErr := i.runHooks(i.ctx, HookPostInstall, ""); hookErr != nil && err == nil {
+			err = hookErr
+		}
+		i.finishExecution(execution, trace.Wrap(err))
+	}()
+	return trace.Wrap(i.awaitExecution(clientCtx, execution))
+}
+
+// awaitExecution waits for execution to complete or clientCtx to be
+// cancelled, whichever comes first, detaching in the latter case.
+func (i *Installer) awaitExecution(clientCtx context.Context, execution *detachedExecution) error {
+	select {
+	case <-execution.done:
+		return trace.Wrap(execution.err)
+	case <-clientCtx.Done():
+		return trace.Wrap(i.Detach(context.Background()))
+	}
+}
+
+// Detach sends the connected dispatcher a progress message saying the
+// operation continues in the background and marks the running operation as
+// detached in storage, then returns without cancelling i.ctx - the
+// supervisor goroutine started by executeDetachable keeps running until the
+// operation completes or a true shutdown signal (Stop/abort) is received.
+// Events still buffered for relayProgressEvents are not waited for.
+// Implements Interface
+func (i *Installer) Detach(ctx context.Context) error {
+	i.Info("Detach.")
+	i.server.Send(server.Event{
+		Progress: &ops.ProgressEntry{
+			Message: "Client disconnected, operation will continue running in the background.",
+		},
+	})
+	op, err := ops.GetWizardOperation(i.config.Operator)
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	if err := i.config.Operator.SetOperationState(op.Key(), ops.SetOperationStateRequest{
+		State: ops.OperationStateDetached,
+	}); err != nil {
+		return trace.Wrap(err)
+	}
 	return nil
 }
 
@@ -273,16 +466,18 @@ func (i *Installer) maybeStartAgent() error {
 }
 
 func (i *Installer) completeOperation(operation ops.SiteOperation, status server.Status) error {
+	ctx := logging.WithOperation(i.ctx, operation.Key())
+	logger := logging.FromContext(ctx)
 	var errors []error
 	if err := i.uploadInstallLog(operation.Key()); err != nil {
 		errors = append(errors, err)
 	}
-	if err := i.emitAuditEvents(i.ctx, operation); err != nil {
+	if err := i.emitAuditEvents(ctx, operation); err != nil {
 		errors = append(errors, err)
 	}
 	// Explicitly stop agents iff the operation has been completed successfully
 	i.addStopper(signals.StopperFunc(func(ctx context.Context) error {
-		i.WithField("operation", operation.ID).Info("Stopping agent service.")
+		logger.WithField("operation", operation.ID).Info("Stopping agent service.")
 		return trace.Wrap(i.config.Process.AgentService().StopAgents(ctx, operation.Key()))
 	}))
 	i.sendElapsedTime(operation.Created)
@@ -303,21 +498,56 @@ func (i *Installer) executePhase(phase installpb.ExecuteRequest_Phase) error {
 	if err != nil {
 		return trace.Wrap(err)
 	}
+	i.setCurrentMachine(machine)
+	defer i.setCurrentMachine(nil)
+	ctx := logging.WithPhase(logging.WithOperation(i.ctx, opKey), phase.ID)
+	if phase.NodeAddr != "" {
+		ctx = logging.WithNode(ctx, phase.NodeAddr)
+	}
 	if phase.IsResume() {
-		return trace.Wrap(i.executeOperation(machine))
+		return trace.Wrap(i.executeOperation(ctx, machine))
 	}
 	params := fsm.Params{
 		PhaseID: phase.ID,
 		Force:   phase.Force,
 	}
+	started := time.Now()
+	i.publish(installevents.PhaseStarted{PhaseID: phase.ID, NodeID: phase.NodeAddr, Time: started})
+	if !phase.Rollback {
+		if err := i.runHooks(ctx, HookPrePhase, phase.ID); err != nil {
+			i.publish(installevents.PhaseCompleted{PhaseID: phase.ID, Duration: time.Since(started), Err: err})
+			return trace.Wrap(err)
+		}
+	}
 	if phase.Rollback {
-		return trace.Wrap(machine.RollbackPhase(i.ctx, params))
+		err = machine.RollbackPhase(ctx, params)
+	} else {
+		err = machine.ExecutePhase(ctx, params)
+		if hookErr := i.runHooks(ctx, HookPostPhase, phase.ID); hookErr != nil && err == nil {
+			err = hookErr
+		}
 	}
-	return trace.Wrap(machine.ExecutePhase(i.ctx, params))
+	i.publish(installevents.PhaseCompleted{PhaseID: phase.ID, Duration: time.Since(started), Err: err})
+	return trace.Wrap(err)
 }
 
-func (i *Installer) executeOperation(machine *fsm.FSM) error {
-	return trace.Wrap(ExecuteOperation(i.ctx, machine, i.FieldLogger))
+// executeOperation resumes a plan that was interrupted mid-install. Since
+// ExecuteOperation drives every remaining phase of the plan in one call
+// rather than phase by phase, the per-phase HookPrePhase/HookPostPhase
+// hooks can't be wrapped around it here - those are run by executePhase
+// for the ordinary single-phase path instead. The operation-level
+// HookPreInstall/HookPostInstall hooks, however, are wrapped here so a
+// resumed operation runs them exactly like a fresh one does in
+// executeDetachable, rather than skipping them.
+func (i *Installer) executeOperation(ctx context.Context, machine *fsm.FSM) error {
+	if err := i.runHooks(ctx, HookPreInstall, ""); err != nil {
+		return trace.Wrap(err)
+	}
+	err := ExecuteOperation(ctx, machine, logging.FromContext(ctx))
+	if hookErr := i.runHooks(ctx, HookPostInstall, ""); hookErr != nil && err == nil {
+		err = hookErr
+	}
+	return trace.Wrap(err)
 }
 
 func (i *Installer) stop() {
@@ -350,6 +580,7 @@ func (i *Installer) stopWithContext(ctx context.Context) error {
 
 // abortWithContext aborts the active operation and invokes the abort handler
 func (i *Installer) abortWithContext(ctx context.Context) error {
+	i.publish(installevents.InstallAborted{Reason: trace.Errorf("operation aborted")})
 	i.cancel()
 	var errors []error
 	for _, c := range i.aborters {
@@ -362,20 +593,27 @@ func (i *Installer) abortWithContext(ctx context.Context) error {
 	return trace.NewAggregate(errors...)
 }
 
+// sendElapsedTime sends the "Installation succeeded in ..." progress message
+// synchronously, directly through i.server rather than through the async
+// event dispatcher, so it's guaranteed to reach the client strictly before
+// the completion banner sendCompletionEvent sends right after it returns.
+// Routing it through publish()/relayProgressEvents instead would let the two
+// messages race, since the dispatcher delivers on its own goroutine with no
+// ordering guarantee relative to a direct i.server.Send from this one.
 func (i *Installer) sendElapsedTime(timeStarted time.Time) {
-	event := server.Event{
-		Progress: &ops.ProgressEntry{
-			Message: color.GreenString("Installation succeeded in %v", time.Since(timeStarted)),
-		},
-	}
-	i.server.Send(event)
+	duration := time.Since(timeStarted)
+	i.server.Send(server.Event{
+		Progress: &ops.ProgressEntry{Message: color.GreenString("Installation succeeded in %v", duration)},
+	})
+	i.publish(installevents.InstallCompleted{Duration: duration, Status: constants.Completed})
 }
 
 // TODO(dmitri): this information should also be displayed when working with the operation
 // manually
 func (i *Installer) sendCompletionEvent(status server.Status) {
 	var buf bytes.Buffer
-	i.printEndpoints(&buf)
+	endpoints := i.printEndpoints(&buf)
+	i.publish(installevents.EndpointsReady{Endpoints: endpoints})
 	if m, ok := modules.Get().(modules.Messager); ok {
 		fmt.Fprintf(&buf, "\n%v", m.PostInstallMessage())
 	}
@@ -400,16 +638,22 @@ func (i *Installer) stopStoppers(ctx context.Context) error {
 	return trace.NewAggregate(errors...)
 }
 
-func (i *Installer) printEndpoints(w io.Writer) {
+// printEndpoints writes the cluster's endpoints to w for the legacy
+// completion message and returns their URLs so the caller can also publish
+// them as an EndpointsReady event.
+func (i *Installer) printEndpoints(w io.Writer) []string {
 	status, err := i.getClusterStatus()
 	if err != nil {
 		i.WithError(err).Error("Failed to collect cluster status.")
-		return
+		return nil
 	}
 	fmt.Fprintln(w)
 	status.Cluster.Endpoints.Cluster.WriteTo(w)
 	fmt.Fprintln(w)
 	status.Cluster.Endpoints.Applications.WriteTo(w)
+	endpoints := append([]string{}, status.Cluster.Endpoints.Cluster.Addrs()...)
+	endpoints = append(endpoints, status.Cluster.Endpoints.Applications.Addrs()...)
+	return endpoints
 }
 
 // getClusterStatus collects status of the installer cluster.
@@ -460,15 +704,16 @@ func (i *Installer) uploadInstallLog(operationKey ops.SiteOperationKey) error {
 // emitAuditEvents sends the install operation's start/finish
 // events to the installed cluster's audit log.
 func (i *Installer) emitAuditEvents(ctx context.Context, operation ops.SiteOperation) error {
+	logger := logging.FromContext(ctx)
 	operator, err := localenv.ClusterOperator()
 	if err != nil {
-		i.WithError(err).Warn("Failed to create cluster operator.")
+		logger.WithError(err).Warn("Failed to create cluster operator.")
 		return trace.Wrap(err)
 	}
 	fields := events.FieldsForOperation(operation)
-	events.Emit(i.ctx, operator, events.OperationInstallStart, fields.WithField(
+	events.Emit(ctx, operator, events.OperationInstallStart, fields.WithField(
 		events.FieldTime, operation.Created))
-	events.Emit(i.ctx, operator, events.OperationInstallComplete, fields)
+	events.Emit(ctx, operator, events.OperationInstallComplete, fields)
 	return nil
 }
 
@@ -496,6 +741,7 @@ func (i *Installer) startAgent(operation ops.SiteOperation) error {
 	go func() {
 		i.agentErrC <- i.agent.ServeWithToken(token)
 	}()
+	i.NotifyAgentJoined(i.config.Role, profile.AgentURL)
 	return nil
 }
 
@@ -510,6 +756,81 @@ func (i *Installer) releaseExecuteToken() {
 	<-i.executeSema
 }
 
+// beginExecute atomically checks that the installer is still healthy and,
+// if so, registers the caller as executing. Checking readiness and
+// registering as executing have to happen under the same lock as drain()'s
+// transition to ReadinessDraining - otherwise a goroutine can observe
+// ReadinessHealthy, then lose the scheduler to drain(), which sees
+// executing's counter still at zero and tears the server down before the
+// goroutine gets around to calling executing.Add(1).
+func (i *Installer) beginExecute() bool {
+	i.lifecycleMu.Lock()
+	defer i.lifecycleMu.Unlock()
+	if i.Readiness() != ReadinessHealthy {
+		return false
+	}
+	i.executing.Add(1)
+	return true
+}
+
+// setCurrentMachine records the FSM driving the phase currently executing,
+// or clears it when nil. Guarded separately from lifecycleMu since it's
+// only read for checkpointing (drain) and hook status bookkeeping
+// (runHooks, recordHookStatus), not for lifecycle decisions.
+func (i *Installer) setCurrentMachine(machine *fsm.FSM) {
+	i.machineMu.Lock()
+	i.machine = machine
+	i.machineMu.Unlock()
+}
+
+func (i *Installer) currentMachine() *fsm.FSM {
+	i.machineMu.Lock()
+	defer i.machineMu.Unlock()
+	return i.machine
+}
+
+// detachedExecution is the completion handle of a detached install running
+// in executeDetachable's supervisor goroutine. done is closed once err is
+// safely readable, so every client attached to the same execution -
+// however many times it has been reattached to - observes the result.
+type detachedExecution struct {
+	done chan struct{}
+	err  error
+}
+
+// attachToRunningExecution returns the already in-flight detached
+// execution, if executeDetachable's supervisor goroutine is still running
+// one, or nil if there is none to reattach to.
+func (i *Installer) attachToRunningExecution() *detachedExecution {
+	i.executionMu.Lock()
+	defer i.executionMu.Unlock()
+	return i.execution
+}
+
+// beginExecution records a new in-flight detached execution so a later,
+// reconnecting call to executeDetachable reattaches to it instead of
+// starting a second one, and returns its completion handle.
+func (i *Installer) beginExecution() *detachedExecution {
+	execution := &detachedExecution{done: make(chan struct{})}
+	i.executionMu.Lock()
+	i.execution = execution
+	i.executionMu.Unlock()
+	return execution
+}
+
+// finishExecution records err as execution's result and wakes up every
+// client waiting on it, then clears it so the next call starts a fresh
+// execution rather than reattaching to this completed one.
+func (i *Installer) finishExecution(execution *detachedExecution, err error) {
+	execution.err = err
+	close(execution.done)
+	i.executionMu.Lock()
+	if i.execution == execution {
+		i.execution = nil
+	}
+	i.executionMu.Unlock()
+}
+
 // Installer manages the installation process
 type Installer struct {
 	// FieldLogger specifies the installer's logger
@@ -530,6 +851,31 @@ type Installer struct {
 	// this explicitly
 	executeSema chan struct{}
 	doneC       chan struct{}
+	// events is the typed event bus for this install operation
+	events *installevents.Dispatcher
+	// executing tracks in-flight Execute calls so drain can wait for the
+	// currently running phase to finish
+	executing sync.WaitGroup
+	// readiness reflects where the installer is in its shutdown lifecycle;
+	// always accessed through Readiness/setReadiness
+	readiness int32
+	// lifecycleMu serializes "check readiness, then register as executing"
+	// against drain()'s "stop accepting work, then wait on executing" so the
+	// two can never interleave and let a phase start after drain has already
+	// decided the installer is done.
+	lifecycleMu sync.Mutex
+	// machineMu guards machine
+	machineMu sync.Mutex
+	// machine is the FSM driving the phase currently executing, if any.
+	// drain uses it to checkpoint plan progress while it waits for the
+	// phase to finish.
+	machine *fsm.FSM
+	// executionMu guards execution
+	executionMu sync.Mutex
+	// execution is the in-flight detached install, if executeDetachable's
+	// supervisor goroutine is currently running one - a reconnecting
+	// client's Execute call reattaches to it instead of starting another.
+	execution *detachedExecution
 }
 
 // Engine implements the process of cluster installation
@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging carries a structured logger on a context.Context, in the
+// style of swarmkit's log.WithLogger. Install phases fan out across the
+// FSM, RPC agents and ops/fsm packages; threading fields through a
+// context-carried logger lets every one of those layers pick up
+// operation_id/phase_id/node_addr correlation fields without having to
+// know about each other.
+package logging
+
+import (
+	"context"
+
+	"github.com/gravitational/gravity/lib/ops"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// WithLogger returns a context derived from ctx that carries logger as the
+// logger returned by FromContext.
+func WithLogger(ctx context.Context, logger log.FieldLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger attached to ctx by WithLogger, or
+// logrus's standard logger if ctx carries none.
+func FromContext(ctx context.Context) log.FieldLogger {
+	if logger, ok := ctx.Value(loggerKey{}).(log.FieldLogger); ok {
+		return logger
+	}
+	return log.StandardLogger()
+}
+
+// WithOperation returns a context whose logger carries the operation_id
+// field for key.
+func WithOperation(ctx context.Context, key ops.SiteOperationKey) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithField("operation_id", key.OperationID))
+}
+
+// WithPhase returns a context whose logger carries the phase_id field.
+func WithPhase(ctx context.Context, phaseID string) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithField("phase_id", phaseID))
+}
+
+// WithNode returns a context whose logger carries the node_addr field.
+func WithNode(ctx context.Context, nodeAddr string) context.Context {
+	return WithLogger(ctx, FromContext(ctx).WithField("node_addr", nodeAddr))
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/gravitational/gravity/lib/ops"
+
+	"github.com/gravitational/trace"
+	"github.com/sirupsen/logrus"
+)
+
+// recorder is a logrus.Hook that keeps every entry fired through it, so a
+// test can inspect the fields every log line actually carried.
+type recorder struct {
+	entries []*logrus.Entry
+}
+
+func (r *recorder) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (r *recorder) Fire(entry *logrus.Entry) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// TestOperationIDOnEveryLogLine drives a mock install plan - an
+// operation-level line followed by a couple of phases, each further
+// scoping the logger with WithPhase/WithNode the same way executePhase
+// does - and asserts operation_id shows up on every line logged anywhere
+// along the way.
+func TestOperationIDOnEveryLogLine(t *testing.T) {
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	rec := &recorder{}
+	logger.Hooks.Add(rec)
+
+	opCtx := WithOperation(WithLogger(context.Background(), logger),
+		ops.SiteOperationKey{OperationID: "operation-1"})
+	FromContext(opCtx).Info("starting operation")
+
+	phases := []struct {
+		id   string
+		node string
+	}{
+		{id: "/init", node: "10.0.0.1"},
+		{id: "/masters", node: "10.0.0.2"},
+	}
+	for _, phase := range phases {
+		phaseCtx := WithNode(WithPhase(opCtx, phase.id), phase.node)
+		FromContext(phaseCtx).Info("executing phase")
+		FromContext(phaseCtx).WithError(trace.Errorf("boom")).Warn("phase step failed")
+	}
+
+	if len(rec.entries) == 0 {
+		t.Fatal("expected log entries to have been recorded")
+	}
+	for _, entry := range rec.entries {
+		operationID, ok := entry.Data["operation_id"]
+		if !ok {
+			t.Fatalf("log entry %q is missing operation_id: %v", entry.Message, entry.Data)
+		}
+		if operationID != "operation-1" {
+			t.Fatalf("log entry %q has operation_id %v, want operation-1", entry.Message, operationID)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2019 Gravitational, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gravitational/gravity/lib/defaults"
+	installevents "github.com/gravitational/gravity/lib/install/events"
+	"github.com/gravitational/gravity/lib/install/server"
+	"github.com/gravitational/gravity/lib/ops"
+)
+
+// ReadinessState reflects where the installer is in its shutdown lifecycle.
+// It is surfaced over the gRPC server as a Readiness event on the same
+// stream progress is sent on, so supervisors (systemd, Nomad, a Kubernetes
+// operator wrapping gravity) can distinguish "healthy and executing" from
+// "draining" from "terminated" without polling.
+type ReadinessState int32
+
+const (
+	// ReadinessHealthy is the installer executing normally and accepting
+	// new phase executions
+	ReadinessHealthy ReadinessState = iota
+	// ReadinessDraining is the installer finishing the phase that was
+	// already running but refusing to start new ones
+	ReadinessDraining
+	// ReadinessTerminated is the installer fully shut down
+	ReadinessTerminated
+)
+
+// String returns the lower-case name of state, as sent in Readiness events.
+func (s ReadinessState) String() string {
+	switch s {
+	case ReadinessHealthy:
+		return "healthy"
+	case ReadinessDraining:
+		return "draining"
+	case ReadinessTerminated:
+		return "terminated"
+	default:
+		return "unknown"
+	}
+}
+
+// Readiness reports the installer's current ReadinessState.
+func (i *Installer) Readiness() ReadinessState {
+	return ReadinessState(atomic.LoadInt32(&i.readiness))
+}
+
+// setReadiness updates the installer's ReadinessState and relays the
+// transition as a Readiness event over i.server, the same gRPC stream
+// Progress events are sent on.
+func (i *Installer) setReadiness(state ReadinessState) {
+	atomic.StoreInt32(&i.readiness, int32(state))
+	i.server.Send(server.Event{Readiness: &ops.ReadinessEntry{State: state.String()}})
+}
+
+// drain enters the draining state: Execute stops accepting new phases but
+// the phase already running (if any) is given up to GracefulShutdownTimeout
+// to finish on its own before the server is interrupted. If the timeout
+// expires first, drain falls back to the existing hard-abort path.
+func (i *Installer) drain(ctx context.Context) error {
+	// Transition under lifecycleMu: beginExecute() checks readiness and
+	// registers as executing under the same lock, so by the time this
+	// store is visible, every goroutine that observed ReadinessHealthy has
+	// already been counted in i.executing and will be waited on below.
+	i.lifecycleMu.Lock()
+	i.setReadiness(ReadinessDraining)
+	i.lifecycleMu.Unlock()
+	defer i.setReadiness(ReadinessTerminated)
+
+	timeout := i.config.GracefulShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaults.GracefulShutdownTimeout
+	}
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	drainedC := make(chan struct{})
+	go func() {
+		i.executing.Wait()
+		close(drainedC)
+	}()
+
+	started := time.Now()
+	ticker := time.NewTicker(defaults.DrainCheckpointInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-drainedC:
+			i.server.Interrupt(ctx)
+			return nil
+		case <-ticker.C:
+			i.publish(installevents.Draining{Elapsed: time.Since(started)})
+			// Checkpoint the running phase's progress (without completing
+			// the operation) so a crash during the grace period doesn't
+			// lose work the phase has already made.
+			if machine := i.currentMachine(); machine != nil {
+				if err := machine.Checkpoint(ctx); err != nil {
+					i.WithError(err).Warn("Failed to checkpoint operation plan progress.")
+				}
+			}
+		case <-drainCtx.Done():
+			i.WithField("timeout", timeout).Warn(
+				"Graceful shutdown timed out waiting for the running phase to finish, aborting.")
+			i.server.Interrupt(ctx)
+			return nil
+		}
+	}
+}
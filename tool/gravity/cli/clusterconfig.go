@@ -17,9 +17,17 @@ limitations under the License.
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"strings"
+	"time"
 
+	"github.com/gravitational/gravity/lib/constants"
 	libfsm "github.com/gravitational/gravity/lib/fsm"
 	"github.com/gravitational/gravity/lib/localenv"
 	"github.com/gravitational/gravity/lib/ops"
@@ -30,18 +38,31 @@ import (
 	"github.com/gravitational/gravity/lib/update/clusterconfig"
 	"github.com/gravitational/gravity/lib/validate"
 
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/ghodss/yaml"
 	"github.com/gravitational/trace"
+	"github.com/pborman/uuid"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // resetConfig executes the loop to reset cluster configuration to defaults
-func resetConfig(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, manual, confirmed bool) error {
+func resetConfig(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, manual, confirmed, dryRun, force bool, output string) error {
 	config := libclusterconfig.NewEmpty()
-	return trace.Wrap(updateConfig(ctx, localEnv, updateEnv, config, manual, confirmed))
+	return trace.Wrap(updateConfig(ctx, localEnv, updateEnv, config, manual, confirmed, dryRun, force, output))
 }
 
-func updateConfig(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, config libclusterconfig.Interface, manual, confirmed bool) error {
-	if err := validateClusterConfig(localEnv, config); err != nil {
+func updateConfig(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, config libclusterconfig.Interface, manual, confirmed, dryRun, force bool, output string) error {
+	// Dry-run always renders the plan, even when there are live CIDR
+	// allocations that would make a real run fail without --force - the
+	// whole point of --dry-run is to let the user see that before
+	// deciding whether to pass --force. Only a real run is gated.
+	if dryRun {
+		return trace.Wrap(printConfigDiff(ctx, localEnv, config))
+	}
+	if err := validateClusterConfig(localEnv, config, force); err != nil {
 		return trace.Wrap(err)
 	}
 	if !confirmed {
@@ -68,10 +89,167 @@ func updateConfig(ctx context.Context, localEnv, updateEnv *localenv.LocalEnviro
 		err = updater.Run(ctx)
 		return trace.Wrap(err)
 	}
-	localEnv.Println(updateConfigManualOperationBanner)
+	return trace.Wrap(printManualOperationPlan(localEnv, updater, output))
+}
+
+// printManualOperationPlan prints the plan generated for a manually-run
+// config update operation. With no output format requested, it falls back
+// to the banner pointing at the docs; with --output=json|yaml it instead
+// emits the generated storage.OperationPlan in a stable schema so external
+// orchestrators (CI/CD pipelines, GitOps controllers) can drive phase
+// execution decisions without scraping interactive CLI output.
+func printManualOperationPlan(localEnv *localenv.LocalEnvironment, updater *update.Updater, output string) error {
+	if output == "" {
+		localEnv.Println(updateConfigManualOperationBanner)
+		return nil
+	}
+	plan, err := updater.GetPlan()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	encoded, err := encodeOperationPlan(plan, output)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localEnv.Println(string(encoded))
 	return nil
 }
 
+// encodeOperationPlan renders plan in the requested output format.
+func encodeOperationPlan(plan *storage.OperationPlan, output string) ([]byte, error) {
+	switch output {
+	case constants.EncodingJSON:
+		encoded, err := json.MarshalIndent(plan, "", "  ")
+		return encoded, trace.Wrap(err)
+	case constants.EncodingYAML:
+		encoded, err := yaml.Marshal(plan)
+		return encoded, trace.Wrap(err)
+	default:
+		return nil, trace.BadParameter("unsupported output format %q, supported are: %v, %v",
+			output, constants.EncodingJSON, constants.EncodingYAML)
+	}
+}
+
+// printConfigDiff computes the operation plan that would result from
+// applying config and renders it together with the resulting CIDR/kubelet
+// deltas, without creating an operation in the backend.
+func printConfigDiff(ctx context.Context, localEnv *localenv.LocalEnvironment, config libclusterconfig.Interface) error {
+	operator, err := localEnv.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	clusterEnv, err := localEnv.NewClusterEnvironment()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	existing, err := operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// A throwaway operation is only used to drive plan generation below and
+	// is never persisted via operator.CreateUpdateConfigOperation.
+	operation := ops.SiteOperation{
+		ID:         uuid.New(),
+		AccountID:  cluster.AccountID,
+		SiteDomain: cluster.Domain,
+		Created:    time.Now().UTC(),
+	}
+	plan, err := clusterconfig.NewOperationPlan(
+		ctx, operator, clusterEnv.Apps, clusterEnv.Client,
+		operation, config, cluster.ClusterState.Servers,
+	)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	// Computed for display only - never gates the preview itself, since
+	// dry-run's whole purpose is to let the user see this before they
+	// decide whether to pass --force.
+	offending, err := cidrsInUse(localEnv, config)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	localEnv.Println(renderConfigDiff(existing, config, plan, offending))
+	return nil
+}
+
+// renderConfigDiff formats the CIDR changes between existing and update
+// together with the phases of plan and any live CIDR allocations that
+// would be orphaned by the change, similar to how `terraform plan` surfaces
+// resource-level changes before apply.
+func renderConfigDiff(existing, update libclusterconfig.Interface, plan *storage.OperationPlan, offendingCIDRs []string) string {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "The following configuration changes would be applied:")
+	if podCIDR := update.GetGlobalConfig().PodCIDR; podCIDR != "" && podCIDR != existing.GetGlobalConfig().PodCIDR {
+		fmt.Fprintf(&buf, "  * pod CIDR: %v -> %v\n", existing.GetGlobalConfig().PodCIDR, podCIDR)
+	}
+	if serviceCIDR := update.GetGlobalConfig().ServiceCIDR; serviceCIDR != "" && serviceCIDR != existing.GetGlobalConfig().ServiceCIDR {
+		fmt.Fprintf(&buf, "  * service CIDR: %v -> %v\n", existing.GetGlobalConfig().ServiceCIDR, serviceCIDR)
+	}
+	if kubeletDiff := diffKubeletConfig(existing, update); kubeletDiff != "" {
+		fmt.Fprintf(&buf, "  * kubelet configuration:\n%v", kubeletDiff)
+	}
+	if len(offendingCIDRs) > 0 {
+		fmt.Fprintln(&buf, "\nWarning: the following allocations fall outside the requested CIDR range and would be orphaned:")
+		for _, entry := range offendingCIDRs {
+			fmt.Fprintf(&buf, "  %v\n", entry)
+		}
+		fmt.Fprintln(&buf, "Running this operation for real will require --force.")
+	}
+	fmt.Fprintln(&buf, "\nThe operation would execute the following phases:")
+	renderPhases(&buf, plan.Phases, 0)
+	return buf.String()
+}
+
+// renderPhases writes phase to buf, indented by depth, and recurses into
+// its nested sub-phases - mirroring flattenPhaseIDs - so a plan whose
+// per-master restart phases are nested under a parent phase (the normal
+// shape for a multi-master update) still has its restarted-servers lines
+// rendered instead of silently dropping them.
+func renderPhases(buf *bytes.Buffer, phases []storage.OperationPhase, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, phase := range phases {
+		fmt.Fprintf(buf, "%v  * %v: %v\n", indent, phase.ID, phase.Description)
+		if servers := restartedServers(phase); len(servers) > 0 {
+			fmt.Fprintf(buf, "%v      runtime containers will be restarted on: %v\n", indent, strings.Join(servers, ", "))
+		}
+		renderPhases(buf, phase.Phases, depth+1)
+	}
+}
+
+// diffKubeletConfig renders the max pods and extra args of the kubelet
+// configuration before and after, one per line, if the kubelet
+// configuration would change between existing and update at all, or "" if
+// it would not. Both lines are printed even if only one of them changed,
+// and other changed fields are not shown.
+func diffKubeletConfig(existing, update libclusterconfig.Interface) string {
+	before, after := existing.GetKubeletConfig(), update.GetKubeletConfig()
+	if after == nil || reflect.DeepEqual(before, after) {
+		return ""
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "      max pods: %v -> %v\n", before.GetMaxPods(), after.GetMaxPods())
+	fmt.Fprintf(&buf, "      extra args: %v -> %v\n", before.GetExtraArgs(), after.GetExtraArgs())
+	return buf.String()
+}
+
+// restartedServers returns the hostnames of the servers whose runtime
+// containers phase will restart as part of executing phase, or nil if the
+// phase doesn't restart anything.
+func restartedServers(phase storage.OperationPhase) []string {
+	if phase.ID != libclusterconfig.PhaseRuntime && phase.ID != libclusterconfig.PhaseKubelet {
+		return nil
+	}
+	var servers []string
+	for _, server := range phase.Servers {
+		servers = append(servers, server.Hostname)
+	}
+	return servers
+}
+
 func newConfigUpdater(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, config libclusterconfig.Interface) (*update.Updater, error) {
 	configBytes, err := libclusterconfig.Marshal(config)
 	if err != nil {
@@ -84,6 +262,119 @@ func newConfigUpdater(ctx context.Context, localEnv, updateEnv *localenv.LocalEn
 	return newUpdater(ctx, localEnv, updateEnv, init, nil)
 }
 
+// updateConfigPatch applies patchDoc to the cluster's existing configuration
+// using either RFC 7396 JSON Merge Patch or RFC 6902 JSON Patch semantics
+// (selected by jsonPatch) and runs the resulting configuration through the
+// regular update flow. Unlike updateConfig, only the fields named in
+// patchDoc are ever changed - everything else is inherited from the
+// existing configuration rather than reset to its zero value.
+func updateConfigPatch(ctx context.Context, localEnv, updateEnv *localenv.LocalEnvironment, patchDoc []byte, jsonPatch, manual, confirmed, dryRun, force bool, output string) error {
+	operator, err := localEnv.SiteOperator()
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	cluster, err := operator.GetLocalSite(ctx)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	existing, err := operator.GetClusterConfiguration(cluster.Key())
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	merged, noop, err := applyConfigPatch(existing, patchDoc, jsonPatch)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if noop {
+		localEnv.Println("No changes to apply, configuration already matches the patch.")
+		return nil
+	}
+	// See the comment in updateConfig: dry-run renders the plan
+	// unconditionally and only a real run is gated on validation.
+	if dryRun {
+		return trace.Wrap(printConfigDiff(ctx, localEnv, merged))
+	}
+	if err := validateClusterConfig(localEnv, merged, force); err != nil {
+		return trace.Wrap(err)
+	}
+	if !confirmed {
+		if manual {
+			localEnv.Println(updateConfigBannerManual)
+		} else {
+			localEnv.Println(updateConfigBanner)
+		}
+		resp, err := confirm()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if !resp {
+			localEnv.Println("Action cancelled by user.")
+			return nil
+		}
+	}
+	configBytes, err := libclusterconfig.Marshal(merged)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	init := configInitializer{
+		resource: configBytes,
+		config:   merged,
+		patch:    patchDoc,
+	}
+	updater, err := newUpdater(ctx, localEnv, updateEnv, init, nil)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	defer updater.Close()
+	if !manual {
+		return trace.Wrap(updater.Run(ctx))
+	}
+	return trace.Wrap(printManualOperationPlan(localEnv, updater, output))
+}
+
+// applyConfigPatch merges patchDoc into existing using either JSON Merge
+// Patch (RFC 7396) or JSON Patch (RFC 6902) semantics and validates the
+// result unmarshals back into a libclusterconfig.Interface. noop is true if
+// applying the patch produced no change, letting the caller short-circuit
+// before even creating an operation.
+func applyConfigPatch(existing libclusterconfig.Interface, patchDoc []byte, jsonPatch bool) (merged libclusterconfig.Interface, noop bool, err error) {
+	existingJSON, err := libclusterconfig.MarshalJSON(existing)
+	if err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	var mergedJSON []byte
+	if jsonPatch {
+		patchOps, err := jsonpatch.DecodePatch(patchDoc)
+		if err != nil {
+			return nil, false, trace.Wrap(err, "invalid JSON Patch document")
+		}
+		mergedJSON, err = patchOps.Apply(existingJSON)
+		if err != nil {
+			return nil, false, trace.Wrap(err, "failed to apply JSON Patch")
+		}
+	} else {
+		mergedJSON, err = jsonpatch.MergePatch(existingJSON, patchDoc)
+		if err != nil {
+			return nil, false, trace.Wrap(err, "failed to apply JSON Merge Patch")
+		}
+	}
+	merged, err = libclusterconfig.UnmarshalJSON(mergedJSON)
+	if err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	// Compare the decoded documents rather than the bytes: the patched
+	// document is re-encoded, so its key order and spacing need not match
+	// existingJSON even when nothing changed.
+	var before, after interface{}
+	if err := json.Unmarshal(existingJSON, &before); err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	if err := json.Unmarshal(mergedJSON, &after); err != nil {
+		return nil, false, trace.Wrap(err)
+	}
+	return merged, reflect.DeepEqual(before, after), nil
+}
+
 func executeConfigPhaseForOperation(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params PhaseParams, operation ops.SiteOperation) error {
 	updateEnv, err := environ.NewUpdateEnv()
 	if err != nil {
@@ -95,9 +386,79 @@ func executeConfigPhaseForOperation(env *localenv.LocalEnvironment, environ Loca
 		return trace.Wrap(err)
 	}
 	defer updater.Close()
+	if params.PlanFile != "" {
+		plan, err := readOperationPlanFile(params.PlanFile)
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		current, err := updater.GetPlan()
+		if err != nil {
+			return trace.Wrap(err)
+		}
+		if err := validatePlanConsistency(current, plan); err != nil {
+			return trace.Wrap(err, "refusing to load plan file %v", params.PlanFile)
+		}
+		if err := updater.SetPlan(*plan); err != nil {
+			return trace.Wrap(err)
+		}
+	}
 	return executeOrForkPhase(env, updater, params, operation)
 }
 
+// validatePlanConsistency verifies that candidate is a plausible stand-in
+// for current - the stored plan for the operation being executed - before
+// it's allowed to replace it. A plan file for a different operation, or one
+// whose set of phases has diverged from what the backend recorded (stale,
+// hand-edited, or generated against a different cluster state), is refused
+// rather than silently desyncing phase state.
+func validatePlanConsistency(current, candidate *storage.OperationPlan) error {
+	if candidate.OperationID != current.OperationID {
+		return trace.BadParameter(
+			"plan file is for operation %v, not the operation being executed (%v)",
+			candidate.OperationID, current.OperationID)
+	}
+	currentPhases := flattenPhaseIDs(current.Phases)
+	candidatePhases := flattenPhaseIDs(candidate.Phases)
+	if !reflect.DeepEqual(currentPhases, candidatePhases) {
+		return trace.BadParameter(
+			"plan file's phases do not match the operation's recorded plan, it may be stale or hand-edited")
+	}
+	return nil
+}
+
+// flattenPhaseIDs returns the IDs of phases, depth-first, including nested
+// sub-phases, so two plans can be compared for having the same shape.
+func flattenPhaseIDs(phases []storage.OperationPhase) []string {
+	var ids []string
+	for _, phase := range phases {
+		ids = append(ids, phase.ID)
+		ids = append(ids, flattenPhaseIDs(phase.Phases)...)
+	}
+	return ids
+}
+
+// readOperationPlanFile reads a storage.OperationPlan previously dumped with
+// updateConfig's --output flag, so an external orchestrator (e.g. an
+// Argo/Tekton pipeline) can hand phase execution decisions back to
+// `gravity plan resume --from-file`. The format is inferred from the file
+// extension, defaulting to JSON.
+func readOperationPlanFile(path string) (*storage.OperationPlan, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var plan storage.OperationPlan
+	if strings.HasSuffix(path, ".yaml") || strings.HasSuffix(path, ".yml") {
+		err = yaml.Unmarshal(data, &plan)
+	} else {
+		err = json.Unmarshal(data, &plan)
+	}
+	if err != nil {
+		return nil, trace.Wrap(err, "failed to parse operation plan file %v", path)
+	}
+	return &plan, nil
+}
+
 func setConfigPhaseForOperation(env *localenv.LocalEnvironment, environ LocalEnvironmentFactory, params SetPhaseParams, operation ops.SiteOperation) error {
 	updateEnv, err := environ.NewUpdateEnv()
 	if err != nil {
@@ -197,6 +558,7 @@ func (r configInitializer) newOperation(operator ops.Operator, cluster ops.Site)
 		ops.CreateUpdateConfigOperationRequest{
 			ClusterKey: cluster.Key(),
 			Config:     r.resource,
+			Patch:      r.patch,
 		},
 	)
 	if err != nil {
@@ -266,9 +628,15 @@ func (configInitializer) updateDeployRequest(req deployAgentsRequest) deployAgen
 type configInitializer struct {
 	resource []byte
 	config   libclusterconfig.Interface
+	// patch is the raw patch document that produced config, if the update
+	// was initiated with --patch/--patch-file. Recorded alongside the
+	// fully resolved config so the operator side can tell what was
+	// intentionally changed from what was inherited from the existing
+	// configuration
+	patch []byte
 }
 
-func validateClusterConfig(localEnv *localenv.LocalEnvironment, update libclusterconfig.Interface) error {
+func validateClusterConfig(localEnv *localenv.LocalEnvironment, update libclusterconfig.Interface, force bool) error {
 	operator, err := localEnv.SiteOperator()
 	if err != nil {
 		return trace.Wrap(err)
@@ -308,9 +676,122 @@ func validateClusterConfig(localEnv *localenv.LocalEnvironment, update libcluste
 		}
 	}
 
+	if err := checkCIDRsInUse(localEnv, update, force); err != nil {
+		return trace.Wrap(err)
+	}
+
 	return nil
 }
 
+// checkCIDRsInUse refuses the update - unless force is set - if any
+// currently allocated pod IP or service cluster IP falls outside the
+// requested PodCIDR/ServiceCIDR. Shrinking a CIDR out from under live
+// allocations leaves them orphaned once the CNI/kube-proxy restart with the
+// new ranges.
+func checkCIDRsInUse(localEnv *localenv.LocalEnvironment, update libclusterconfig.Interface, force bool) error {
+	offending, err := cidrsInUse(localEnv, update)
+	if err != nil {
+		return trace.Wrap(err)
+	}
+	if len(offending) == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("the following allocations fall outside the requested CIDR range and would be orphaned:\n  %v",
+		strings.Join(offending, "\n  "))
+	if force {
+		localEnv.Println(fmt.Sprintf("Warning: %v", message))
+		return nil
+	}
+	return trace.BadParameter("%v\nRerun with --force to proceed anyway.", message)
+}
+
+// cidrsInUse lists every currently allocated pod IP and service cluster IP
+// that falls outside the PodCIDR/ServiceCIDR requested by update. It never
+// fails the caller on its own - checkCIDRsInUse decides whether a non-empty
+// result should be a hard error or just a warning.
+func cidrsInUse(localEnv *localenv.LocalEnvironment, update libclusterconfig.Interface) ([]string, error) {
+	podCIDR := update.GetGlobalConfig().PodCIDR
+	serviceCIDR := update.GetGlobalConfig().ServiceCIDR
+	if podCIDR == "" && serviceCIDR == "" {
+		return nil, nil
+	}
+	clusterEnv, err := localEnv.NewClusterEnvironment()
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var offending []string
+	if podCIDR != "" {
+		orphaned, err := podsOutsideCIDR(clusterEnv.Client, podCIDR)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		offending = append(offending, orphaned...)
+	}
+	if serviceCIDR != "" {
+		orphaned, err := servicesOutsideCIDR(clusterEnv.Client, serviceCIDR)
+		if err != nil {
+			return nil, trace.Wrap(err)
+		}
+		offending = append(offending, orphaned...)
+	}
+	return offending, nil
+}
+
+// podsOutsideCIDR returns a description of every pod whose assigned IP
+// falls outside podCIDR. hostNetwork pods are skipped since their PodIP is
+// the node's own address and was never allocated from the pod CIDR, and so
+// are pods that have already terminated, whose PodIP can be stale.
+func podsOutsideCIDR(client *kubernetes.Clientset, podCIDR string) ([]string, error) {
+	_, cidr, err := net.ParseCIDR(podCIDR)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var offending []string
+	for _, pod := range pods.Items {
+		if pod.Spec.HostNetwork {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		ip := net.ParseIP(pod.Status.PodIP)
+		if ip == nil || cidr.Contains(ip) {
+			continue
+		}
+		offending = append(offending, fmt.Sprintf("pod %v/%v (%v)", pod.Namespace, pod.Name, pod.Status.PodIP))
+	}
+	return offending, nil
+}
+
+// servicesOutsideCIDR returns a description of every service whose cluster
+// IP falls outside serviceCIDR.
+func servicesOutsideCIDR(client *kubernetes.Clientset, serviceCIDR string) ([]string, error) {
+	_, cidr, err := net.ParseCIDR(serviceCIDR)
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	services, err := client.CoreV1().Services(metav1.NamespaceAll).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, trace.Wrap(err)
+	}
+	var offending []string
+	for _, service := range services.Items {
+		if service.Spec.ClusterIP == "" || service.Spec.ClusterIP == "None" {
+			continue
+		}
+		ip := net.ParseIP(service.Spec.ClusterIP)
+		if ip == nil || cidr.Contains(ip) {
+			continue
+		}
+		offending = append(offending, fmt.Sprintf("service %v/%v (%v)", service.Namespace, service.Name, service.Spec.ClusterIP))
+	}
+	return offending, nil
+}
+
 const (
 	updateConfigBanner = `Updating cluster configuration might require restart of runtime containers on master nodes.
 The operation might take a few minutes to complete.